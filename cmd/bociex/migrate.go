@@ -0,0 +1,61 @@
+// cmd/bociex/migrate.go
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/michaldziurowski/bociex/internal/db"
+)
+
+// runMigrateCmd implements `bociex migrate up|down|status`.
+func runMigrateCmd(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dsn := fs.String("db", "bociex.db", "path to the sqlite database file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	rest := fs.Args()
+	if len(rest) < 1 {
+		return fmt.Errorf("usage: bociex migrate up|down|status")
+	}
+
+	conn, err := sql.Open("sqlite", *dsn)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	switch rest[0] {
+	case "up":
+		return db.Migrate(ctx, conn)
+	case "down":
+		n := 1
+		if len(rest) > 1 {
+			if _, err := fmt.Sscanf(rest[1], "%d", &n); err != nil {
+				return fmt.Errorf("invalid step count %q", rest[1])
+			}
+		}
+		return db.Rollback(ctx, conn, n)
+	case "status":
+		statuses, err := db.Status(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%-8s %s\n", state, s.Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate subcommand %q", rest[0])
+	}
+}
@@ -1,97 +0,0 @@
-// internal/db/migrate.go
-package db
-
-import (
-	"context"
-	"database/sql"
-	"embed"
-	"fmt"
-	"log/slog"
-	"sort"
-	"strings"
-)
-
-//go:embed migrations/*.sql
-var migrationsFS embed.FS
-
-func Migrate(ctx context.Context, db *sql.DB) error {
-	if err := createMigrationsTable(ctx, db); err != nil {
-		return err
-	}
-	applied, err := getAppliedMigrations(ctx, db)
-	if err != nil {
-		return err
-	}
-	pending, err := getPendingMigrations(applied)
-	if err != nil {
-		return err
-	}
-	for _, name := range pending {
-		if err := runMigration(ctx, db, name); err != nil {
-			return fmt.Errorf("migration %s: %w", name, err)
-		}
-		slog.Info("applied migration", "name", name)
-	}
-	return nil
-}
-
-func createMigrationsTable(ctx context.Context, db *sql.DB) error {
-	_, err := db.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS schema_migrations (
-			version TEXT PRIMARY KEY,
-			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	return err
-}
-
-func getAppliedMigrations(ctx context.Context, db *sql.DB) (map[string]bool, error) {
-	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	applied := make(map[string]bool)
-	for rows.Next() {
-		var version string
-		if err := rows.Scan(&version); err != nil {
-			return nil, err
-		}
-		applied[version] = true
-	}
-	return applied, rows.Err()
-}
-
-func getPendingMigrations(applied map[string]bool) ([]string, error) {
-	entries, err := migrationsFS.ReadDir("migrations")
-	if err != nil {
-		return nil, err
-	}
-	var pending []string
-	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(e.Name(), ".sql") && !applied[e.Name()] {
-			pending = append(pending, e.Name())
-		}
-	}
-	sort.Strings(pending)
-	return pending, nil
-}
-
-func runMigration(ctx context.Context, db *sql.DB, name string) error {
-	content, err := migrationsFS.ReadFile("migrations/" + name)
-	if err != nil {
-		return err
-	}
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-	if _, err := tx.ExecContext(ctx, string(content)); err != nil {
-		return err
-	}
-	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES (?)", name); err != nil {
-		return err
-	}
-	return tx.Commit()
-}
@@ -0,0 +1,123 @@
+// internal/db/checksum.go
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+)
+
+// checksumFor returns the SHA-256 checksum of a migration's on-disk
+// contents, keyed by version. Go migrations (registered via
+// RegisterGoMigration) have no on-disk file to hash, so they report an
+// empty checksum and are exempt from drift detection.
+func checksumFor(dialect Dialect, version string) (string, error) {
+	if _, ok := registeredGoMigrations[version]; ok {
+		return "", nil
+	}
+	path, err := resolveMigrationPath(dialect, version)
+	if err != nil {
+		return "", err
+	}
+	content, err := migrationsFS.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// checksumBootstrapMigration adds the checksum column to schema_migrations
+// and backfills it for rows that predate the column. It's registered as a Go
+// migration rather than run as ad hoc DDL so its rollout is versioned and
+// visible through Status like any other migration, per the request that
+// introduced checksum drift detection. applyChecksumBootstrap runs it ahead
+// of the rest of Migrate, since the rest of Migrate needs the column to
+// already exist to even compute which migrations are pending.
+const checksumBootstrapMigration = "0000_schema_migrations_checksum"
+
+func init() {
+	RegisterGoMigration(checksumBootstrapMigration, addChecksumColumnUp, nil)
+}
+
+// applyChecksumBootstrap runs checksumBootstrapMigration through the normal
+// runMigration path, if it hasn't already been applied.
+func applyChecksumBootstrap(ctx context.Context, db DBConn, dialect Dialect) error {
+	applied, err := getAppliedMigrations(ctx, db)
+	if err != nil {
+		return err
+	}
+	if applied[checksumBootstrapMigration] {
+		return nil
+	}
+	return runMigration(ctx, db, dialect, checksumBootstrapMigration)
+}
+
+func addChecksumColumnUp(ctx context.Context, tx *sql.Tx) error {
+	dialect := currentDialect()
+	has, err := dialect.HasColumn(ctx, tx, "schema_migrations", "checksum")
+	if err != nil {
+		return err
+	}
+	if !has {
+		if _, err := tx.ExecContext(ctx, "ALTER TABLE schema_migrations ADD COLUMN checksum TEXT"); err != nil {
+			return fmt.Errorf("add checksum column: %w", err)
+		}
+	}
+	return backfillChecksums(ctx, tx, dialect)
+}
+
+func backfillChecksums(ctx context.Context, db execer, dialect Dialect) error {
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations WHERE checksum IS NULL OR checksum = ''")
+	if err != nil {
+		return err
+	}
+	var versions []string
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, version := range versions {
+		sum, err := checksumFor(dialect, version)
+		if err != nil {
+			// The migration that produced this row no longer exists on disk;
+			// leave its checksum unset and let IgnoreUnknown govern it.
+			continue
+		}
+		update := fmt.Sprintf("UPDATE schema_migrations SET checksum = %s WHERE version = %s",
+			dialect.Placeholder(1), dialect.Placeholder(2))
+		if _, err := db.ExecContext(ctx, update, sum, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getAppliedChecksums(ctx context.Context, db execer) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version, checksum FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[string]string)
+	for rows.Next() {
+		var version string
+		var checksum sql.NullString
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, err
+		}
+		applied[version] = checksum.String
+	}
+	return applied, rows.Err()
+}
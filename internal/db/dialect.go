@@ -0,0 +1,237 @@
+// internal/db/dialect.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Dialect isolates the handful of places migrate.go and time.go need to
+// differ between SQL backends, so that bociex can run against Postgres or
+// MySQL in production while keeping SQLite for tests.
+type Dialect interface {
+	// Name identifies the dialect, and is also the migrations/<Name>/
+	// subdirectory consulted for dialect-specific migrations.
+	Name() string
+	// Placeholder returns the bind-parameter syntax for the i'th (1-based)
+	// argument in a query.
+	Placeholder(i int) string
+	// CreateMigrationsTableSQL returns the DDL used to bootstrap the
+	// schema_migrations bookkeeping table.
+	CreateMigrationsTableSQL() string
+	// CreateSeedsTableSQL returns the DDL used to bootstrap the schema_seeds
+	// bookkeeping table.
+	CreateSeedsTableSQL() string
+	// TimeColumnType returns the column type used to store a Time value.
+	TimeColumnType() string
+	// TimeScan converts a driver value read back from a Time column.
+	TimeScan(value interface{}) (time.Time, error)
+	// TimeValue converts t into the driver.Value this dialect expects.
+	TimeValue(t time.Time) (driver.Value, error)
+	// HasColumn reports whether table already has the given column, used by
+	// bootstrap migrations that backfill new columns onto existing tables.
+	HasColumn(ctx context.Context, db execer, table, column string) (bool, error)
+}
+
+// DialectForDriver picks the Dialect matching db's driver, sniffed from its
+// concrete driver type (database/sql has no driver-name accessor).
+func DialectForDriver(db *sql.DB) Dialect {
+	switch driverName(db) {
+	case "postgres":
+		return postgresDialect{}
+	case "mysql":
+		return mysqlDialect{}
+	default:
+		return sqliteDialect{}
+	}
+}
+
+// sqliteDialect targets SQLite, which has no real time type and only ever
+// takes "?" placeholders.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string             { return "sqlite" }
+func (sqliteDialect) Placeholder(i int) string { return "?" }
+func (sqliteDialect) TimeColumnType() string   { return "DATETIME" }
+
+func (sqliteDialect) CreateMigrationsTableSQL() string {
+	return `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version TEXT PRIMARY KEY,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+}
+
+func (sqliteDialect) CreateSeedsTableSQL() string {
+	return `
+		CREATE TABLE IF NOT EXISTS schema_seeds (
+			name TEXT PRIMARY KEY,
+			env TEXT NOT NULL,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+}
+
+func (sqliteDialect) TimeScan(value interface{}) (time.Time, error) {
+	if value == nil {
+		return time.Time{}, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("expected string, got %T", value)
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func (sqliteDialect) TimeValue(t time.Time) (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+func (sqliteDialect) HasColumn(ctx context.Context, db execer, table, column string) (bool, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// postgresDialect targets Postgres, which has a real timestamptz type and
+// numbered "$1" placeholders.
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string           { return "postgres" }
+func (postgresDialect) TimeColumnType() string { return "TIMESTAMPTZ" }
+
+func (postgresDialect) Placeholder(i int) string {
+	return fmt.Sprintf("$%d", i)
+}
+
+func (postgresDialect) CreateMigrationsTableSQL() string {
+	return `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ DEFAULT now()
+		)
+	`
+}
+
+func (postgresDialect) CreateSeedsTableSQL() string {
+	return `
+		CREATE TABLE IF NOT EXISTS schema_seeds (
+			name TEXT PRIMARY KEY,
+			env TEXT NOT NULL,
+			applied_at TIMESTAMPTZ DEFAULT now()
+		)
+	`
+}
+
+func (postgresDialect) TimeScan(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return v, nil
+	default:
+		return time.Time{}, fmt.Errorf("expected time.Time, got %T", value)
+	}
+}
+
+func (postgresDialect) TimeValue(t time.Time) (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return t.UTC(), nil
+}
+
+func (postgresDialect) HasColumn(ctx context.Context, db execer, table, column string) (bool, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_name = $1 AND column_name = $2
+		)
+	`, table, column).Scan(&exists)
+	return exists, err
+}
+
+// mysqlDialect targets MySQL/MariaDB, which shares SQLite's "?" placeholders
+// but, with parseTime=true in the DSN, scans DATETIME columns as time.Time.
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string             { return "mysql" }
+func (mysqlDialect) Placeholder(i int) string { return "?" }
+func (mysqlDialect) TimeColumnType() string   { return "DATETIME" }
+
+func (mysqlDialect) CreateMigrationsTableSQL() string {
+	return `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+}
+
+func (mysqlDialect) CreateSeedsTableSQL() string {
+	return `
+		CREATE TABLE IF NOT EXISTS schema_seeds (
+			name VARCHAR(255) PRIMARY KEY,
+			env VARCHAR(255) NOT NULL,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+}
+
+func (mysqlDialect) TimeScan(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return v, nil
+	case []byte:
+		return time.Parse("2006-01-02 15:04:05", string(v))
+	default:
+		return time.Time{}, fmt.Errorf("expected time.Time or []byte, got %T", value)
+	}
+}
+
+func (mysqlDialect) TimeValue(t time.Time) (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return t.UTC(), nil
+}
+
+func (mysqlDialect) HasColumn(ctx context.Context, db execer, table, column string) (bool, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_name = ? AND column_name = ?
+		)
+	`, table, column).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,36 @@
+// internal/db/go_migrations.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// goMigration is a migration expressed in Go rather than SQL, for changes
+// that can't be done with a plain statement against the target database
+// (re-encoding a column, backfilling from an external API, splitting a JSON
+// blob into new rows, ...).
+type goMigration struct {
+	version string
+	up      func(ctx context.Context, tx *sql.Tx) error
+	down    func(ctx context.Context, tx *sql.Tx) error
+}
+
+var registeredGoMigrations = map[string]goMigration{}
+
+// RegisterGoMigration registers a Go-code migration under version, to be
+// merged into the same ordered list and schema_migrations bookkeeping used
+// for the embedded .sql files. It is meant to be called from an init()
+// function in the package defining the migration, mirroring how database/sql
+// drivers register themselves. down may be nil if the migration cannot be
+// reverted.
+func RegisterGoMigration(version string, up, down func(ctx context.Context, tx *sql.Tx) error) {
+	if _, exists := registeredGoMigrations[version]; exists {
+		panic(fmt.Sprintf("db: go migration %q already registered", version))
+	}
+	if up == nil {
+		panic(fmt.Sprintf("db: go migration %q registered with a nil up func", version))
+	}
+	registeredGoMigrations[version] = goMigration{version: version, up: up, down: down}
+}
@@ -0,0 +1,157 @@
+// internal/db/lock.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+)
+
+// Locker acquires and releases a cross-process lock around the migration
+// apply loop, so that two bociex processes starting concurrently against the
+// same database can't both try to apply the same pending migration. Lock
+// hands back the dedicated connection the lock was acquired on — the apply
+// loop must run every subsequent statement through that same connection
+// rather than the pool, or (for sqliteLocker in particular) it would block
+// waiting on a lock it already holds on a different connection. unlock is
+// called, with that connection already closed, once the migration run
+// (success or failure) is complete.
+type Locker interface {
+	Lock(ctx context.Context, db *sql.DB) (conn *sql.Conn, unlock func(context.Context) error, err error)
+}
+
+const lockName = "bociex_migrations"
+
+// defaultLocker picks a Locker appropriate for db's driver, sniffed from its
+// concrete driver type since database/sql has no driver-name accessor.
+func defaultLocker(db *sql.DB) Locker {
+	switch driverName(db) {
+	case "postgres":
+		return postgresLocker{}
+	case "mysql":
+		return mysqlLocker{}
+	default:
+		return sqliteLocker{}
+	}
+}
+
+func driverName(db *sql.DB) string {
+	t := strings.ToLower(fmt.Sprintf("%T", db.Driver()))
+	switch {
+	case strings.Contains(t, "postgres") || strings.Contains(t, "pq."):
+		return "postgres"
+	case strings.Contains(t, "mysql"):
+		return "mysql"
+	default:
+		return "sqlite"
+	}
+}
+
+// sqliteBusyTimeout bounds how long sqliteLocker waits for a concurrent
+// bociex process to release the lock before giving up.
+const sqliteBusyTimeout = 30 * time.Second
+
+// sqliteLocker claims SQLite's write lock on a dedicated connection and keeps
+// it held for the rest of the migration run, so a second bociex process
+// blocks (for up to sqliteBusyTimeout) instead of racing us. busy_timeout
+// makes SQLite's own busy handler retry BEGIN IMMEDIATE with backoff instead
+// of failing the instant a concurrent process already holds the lock; it
+// forces the lock to be taken (and any conflicting holder to be waited on)
+// right away rather than on the first write. Switching the connection to
+// EXCLUSIVE locking_mode first makes SQLite keep holding that lock across the
+// commit below, so the apply loop's own per-migration transactions on this
+// same connection see it as already acquired instead of blocking on it.
+type sqliteLocker struct{}
+
+func (sqliteLocker) Lock(ctx context.Context, db *sql.DB) (*sql.Conn, func(context.Context) error, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA busy_timeout=%d", sqliteBusyTimeout.Milliseconds())); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA locking_mode=EXCLUSIVE"); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations_lock (id INTEGER PRIMARY KEY)"); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "INSERT OR IGNORE INTO schema_migrations_lock (id) VALUES (1)"); err != nil {
+		conn.ExecContext(ctx, "ROLLBACK")
+		conn.Close()
+		return nil, nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, func(ctx context.Context) error {
+		defer conn.Close()
+		_, err := conn.ExecContext(ctx, "PRAGMA locking_mode=NORMAL")
+		return err
+	}, nil
+}
+
+// postgresLocker uses a session-level advisory lock, which must be acquired
+// and released on the same connection.
+type postgresLocker struct{}
+
+func (postgresLocker) Lock(ctx context.Context, db *sql.DB) (*sql.Conn, func(context.Context) error, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := lockKey(lockName)
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, func(ctx context.Context) error {
+		defer conn.Close()
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key)
+		return err
+	}, nil
+}
+
+// mysqlLocker uses MySQL's named GET_LOCK/RELEASE_LOCK, which are also
+// connection-scoped.
+type mysqlLocker struct{}
+
+func (mysqlLocker) Lock(ctx context.Context, db *sql.DB) (*sql.Conn, func(context.Context) error, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var acquired int
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 30)", lockName).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if acquired != 1 {
+		conn.Close()
+		return nil, nil, fmt.Errorf("db: timed out acquiring MySQL lock %q", lockName)
+	}
+	return conn, func(ctx context.Context) error {
+		defer conn.Close()
+		_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", lockName)
+		return err
+	}, nil
+}
+
+// lockKey hashes name into the int64 advisory-lock key pg_advisory_lock expects.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,440 @@
+// internal/db/migrate.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+const (
+	upMarker   = "-- +migrate Up"
+	downMarker = "-- +migrate Down"
+)
+
+// execer is satisfied by *sql.DB, *sql.Conn, and *sql.Tx, so helpers that
+// only ever run a query or exec directly (never starting their own nested
+// transaction) can be handed any of the three, including the *sql.Tx a Go
+// migration's up/down func runs in.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// DBConn is satisfied by both *sql.DB and *sql.Conn, so migration queries
+// that need to start their own transaction (runMigration, revertMigration)
+// can run either against the pool or against a single locked connection (see
+// Locker) without duplicating every helper.
+type DBConn interface {
+	execer
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Migrator applies migrations with the default, strict settings: it refuses
+// to run if an applied migration's checksum has drifted or an applied
+// migration is missing from disk, and it refuses to apply a migration that
+// sorts before one already applied. Use a Migrator directly to relax either
+// check during development.
+type Migrator struct {
+	// AllowOutOfOrder permits applying a pending migration whose version
+	// sorts before the most recently applied one, instead of failing.
+	AllowOutOfOrder bool
+	// IgnoreUnknown permits schema_migrations rows with no corresponding
+	// on-disk file or registered Go migration, instead of failing.
+	IgnoreUnknown bool
+	// Locker guards the apply loop against concurrent bociex processes
+	// migrating the same database. If nil, it's chosen based on db's driver.
+	Locker Locker
+	// Dialect selects the DDL, placeholder syntax, and migrations/<name>/
+	// directory to use. If nil, it's chosen based on db's driver.
+	Dialect Dialect
+}
+
+// Migrate applies all pending migrations using the default, strict Migrator.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	return (&Migrator{}).Migrate(ctx, db)
+}
+
+func (m *Migrator) Migrate(ctx context.Context, db *sql.DB) error {
+	dialect := m.Dialect
+	if dialect == nil {
+		dialect = DialectForDriver(db)
+	}
+	SetDialect(dialect)
+
+	// Acquire the cross-process lock before touching schema at all, and run
+	// every statement for the rest of this call through the locked
+	// connection it hands back: for sqliteLocker that connection is the one
+	// holding the BEGIN IMMEDIATE reserved lock, and issuing writes against a
+	// different pooled connection would deadlock against ourselves.
+	locker := m.Locker
+	if locker == nil {
+		locker = defaultLocker(db)
+	}
+	conn, unlock, err := locker.Lock(ctx, db)
+	if err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer unlock(ctx)
+
+	if err := createMigrationsTable(ctx, conn, dialect); err != nil {
+		return err
+	}
+	// The checksum column itself is added by a registered Go migration (see
+	// checksum.go) rather than ad hoc DDL here, so its rollout is versioned
+	// and visible through Status like any other migration. It has to run
+	// ahead of the rest of this function, though, since getAppliedChecksums
+	// below selects that column.
+	if err := applyChecksumBootstrap(ctx, conn, dialect); err != nil {
+		return err
+	}
+
+	all, err := listMigrations(dialect)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(all))
+	for _, name := range all {
+		known[name] = true
+	}
+
+	appliedChecksums, err := getAppliedChecksums(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if !m.IgnoreUnknown {
+		for version := range appliedChecksums {
+			if !known[version] {
+				return fmt.Errorf("migration %s is recorded as applied but no longer exists on disk or in the Go migration registry", version)
+			}
+		}
+	}
+	for version, recorded := range appliedChecksums {
+		if recorded == "" || !known[version] {
+			continue
+		}
+		current, err := checksumFor(dialect, version)
+		if err != nil {
+			return err
+		}
+		if current != recorded {
+			return fmt.Errorf("migration %s has changed since it was applied (recorded checksum %s, on-disk checksum %s); edit a new migration instead of a deployed one", version, recorded, current)
+		}
+	}
+
+	applied := make(map[string]bool, len(appliedChecksums))
+	for version := range appliedChecksums {
+		applied[version] = true
+	}
+	pending, err := getPendingMigrations(dialect, applied)
+	if err != nil {
+		return err
+	}
+	if !m.AllowOutOfOrder {
+		if last := maxAppliedVersion(applied); last != "" {
+			for _, name := range pending {
+				if name < last {
+					return fmt.Errorf("migration %s is out of order: %s is already applied; set Migrator.AllowOutOfOrder to allow this", name, last)
+				}
+			}
+		}
+	}
+	for _, name := range pending {
+		if err := runMigration(ctx, conn, dialect, name); err != nil {
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+		slog.Info("applied migration", "name", name)
+	}
+	return nil
+}
+
+func maxAppliedVersion(applied map[string]bool) string {
+	var last string
+	for version := range applied {
+		if version > last {
+			last = version
+		}
+	}
+	return last
+}
+
+// Rollback reverts the n most recently applied migrations, in reverse order,
+// by running their Down sections. It is a no-op if n <= 0.
+func Rollback(ctx context.Context, db *sql.DB, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	dialect := DialectForDriver(db)
+	SetDialect(dialect)
+	if err := createMigrationsTable(ctx, db, dialect); err != nil {
+		return err
+	}
+	names, err := getAppliedMigrationsOrdered(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(names) > n {
+		names = names[len(names)-n:]
+	}
+	for i := len(names) - 1; i >= 0; i-- {
+		name := names[i]
+		if err := revertMigration(ctx, db, dialect, name); err != nil {
+			return fmt.Errorf("rollback %s: %w", name, err)
+		}
+		slog.Info("reverted migration", "name", name)
+	}
+	return nil
+}
+
+// MigrationStatus describes whether a single migration has been applied.
+type MigrationStatus struct {
+	Name    string
+	Applied bool
+}
+
+// Status reports every known migration alongside whether it has been applied.
+func Status(ctx context.Context, db *sql.DB) ([]MigrationStatus, error) {
+	dialect := DialectForDriver(db)
+	SetDialect(dialect)
+	if err := createMigrationsTable(ctx, db, dialect); err != nil {
+		return nil, err
+	}
+	applied, err := getAppliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	all, err := listMigrations(dialect)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(all))
+	for _, name := range all {
+		statuses = append(statuses, MigrationStatus{Name: name, Applied: applied[name]})
+	}
+	return statuses, nil
+}
+
+func createMigrationsTable(ctx context.Context, db execer, dialect Dialect) error {
+	_, err := db.ExecContext(ctx, dialect.CreateMigrationsTableSQL())
+	return err
+}
+
+func getAppliedMigrations(ctx context.Context, db execer) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+func getAppliedMigrationsOrdered(ctx context.Context, db execer) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations ORDER BY version ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		names = append(names, version)
+	}
+	return names, rows.Err()
+}
+
+// migrationDir returns the entries of a migrations/ subdirectory, tolerating
+// it not existing (e.g. a dialect with no dialect-specific overrides).
+func migrationDir(dir string) ([]string, error) {
+	entries, err := migrationsFS.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".sql") {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// resolveMigrationPath finds name under migrations/<dialect>/, falling back
+// to the shared migrations/common/ directory.
+func resolveMigrationPath(dialect Dialect, name string) (string, error) {
+	dialectPath := "migrations/" + dialect.Name() + "/" + name
+	if _, err := migrationsFS.ReadFile(dialectPath); err == nil {
+		return dialectPath, nil
+	}
+	commonPath := "migrations/common/" + name
+	if _, err := migrationsFS.ReadFile(commonPath); err == nil {
+		return commonPath, nil
+	}
+	return "", fmt.Errorf("migration %s not found under migrations/%s/ or migrations/common/", name, dialect.Name())
+}
+
+// listMigrations returns every known migration version for dialect: the
+// embedded .sql files under migrations/<dialect>/ and migrations/common/,
+// plus any migrations registered via RegisterGoMigration, sorted together
+// into the single order they're applied in.
+func listMigrations(dialect Dialect) ([]string, error) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range []string{"migrations/" + dialect.Name(), "migrations/common"} {
+		entries, err := migrationDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range entries {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	for version := range registeredGoMigrations {
+		if !seen[version] {
+			seen[version] = true
+			names = append(names, version)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func getPendingMigrations(dialect Dialect, applied map[string]bool) ([]string, error) {
+	all, err := listMigrations(dialect)
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, name := range all {
+		if !applied[name] {
+			pending = append(pending, name)
+		}
+	}
+	return pending, nil
+}
+
+func runMigration(ctx context.Context, db DBConn, dialect Dialect, name string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if gm, ok := registeredGoMigrations[name]; ok {
+		if err := gm.up(ctx, tx); err != nil {
+			return err
+		}
+	} else {
+		up, _, err := readMigrationSections(dialect, name)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, up); err != nil {
+			return err
+		}
+	}
+	checksum, err := checksumFor(dialect, name)
+	if err != nil {
+		return err
+	}
+	insert := fmt.Sprintf("INSERT INTO schema_migrations (version, checksum) VALUES (%s, %s)",
+		dialect.Placeholder(1), dialect.Placeholder(2))
+	if _, err := tx.ExecContext(ctx, insert, name, checksum); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func revertMigration(ctx context.Context, db DBConn, dialect Dialect, name string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if gm, ok := registeredGoMigrations[name]; ok {
+		if gm.down == nil {
+			return fmt.Errorf("migration %s has no down func", name)
+		}
+		if err := gm.down(ctx, tx); err != nil {
+			return err
+		}
+	} else {
+		_, down, err := readMigrationSections(dialect, name)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(down) == "" {
+			return fmt.Errorf("migration %s has no Down section", name)
+		}
+		if _, err := tx.ExecContext(ctx, down); err != nil {
+			return err
+		}
+	}
+	del := fmt.Sprintf("DELETE FROM schema_migrations WHERE version = %s", dialect.Placeholder(1))
+	if _, err := tx.ExecContext(ctx, del, name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// readMigrationSections splits a migration file's contents into its Up and
+// Down sections, delimited by "-- +migrate Up" / "-- +migrate Down" marker
+// comments (the same convention used by sql-migrate and goose). A file with
+// no markers at all is treated as Up-only, for backwards compatibility with
+// migrations written before Down support existed.
+func readMigrationSections(dialect Dialect, name string) (up, down string, err error) {
+	path, err := resolveMigrationPath(dialect, name)
+	if err != nil {
+		return "", "", err
+	}
+	content, err := migrationsFS.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	return splitMigrationSections(string(content))
+}
+
+// splitMigrationSections does the actual marker-based splitting for
+// readMigrationSections, pulled out so it can be tested without the embedded
+// filesystem.
+func splitMigrationSections(text string) (up, down string, err error) {
+	upIdx := strings.Index(text, upMarker)
+	downIdx := strings.Index(text, downMarker)
+	if upIdx < 0 && downIdx < 0 {
+		return text, "", nil
+	}
+	if upIdx >= 0 && downIdx >= 0 {
+		if upIdx < downIdx {
+			up = text[upIdx+len(upMarker) : downIdx]
+			down = text[downIdx+len(downMarker):]
+		} else {
+			down = text[downIdx+len(downMarker) : upIdx]
+			up = text[upIdx+len(upMarker):]
+		}
+		return up, down, nil
+	}
+	if upIdx >= 0 {
+		return text[upIdx+len(upMarker):], "", nil
+	}
+	return "", text[downIdx+len(downMarker):], nil
+}
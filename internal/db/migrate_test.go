@@ -0,0 +1,76 @@
+// internal/db/migrate_test.go
+package db
+
+import "testing"
+
+func TestReadMigrationSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantUp   string
+		wantDown string
+	}{
+		{
+			name:     "up and down",
+			content:  "-- +migrate Up\nCREATE TABLE t (id INTEGER);\n-- +migrate Down\nDROP TABLE t;\n",
+			wantUp:   "\nCREATE TABLE t (id INTEGER);\n",
+			wantDown: "\nDROP TABLE t;\n",
+		},
+		{
+			name:     "down before up",
+			content:  "-- +migrate Down\nDROP TABLE t;\n-- +migrate Up\nCREATE TABLE t (id INTEGER);\n",
+			wantUp:   "\nCREATE TABLE t (id INTEGER);\n",
+			wantDown: "\nDROP TABLE t;\n",
+		},
+		{
+			name:     "down section only",
+			content:  "-- +migrate Down\nDROP TABLE t;\n",
+			wantUp:   "",
+			wantDown: "\nDROP TABLE t;\n",
+		},
+		{
+			name:     "no markers treated as up-only",
+			content:  "CREATE TABLE t (id INTEGER);\n",
+			wantUp:   "CREATE TABLE t (id INTEGER);\n",
+			wantDown: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up, down, err := splitMigrationSections(tt.content)
+			if err != nil {
+				t.Fatalf("splitMigrationSections: %v", err)
+			}
+			if up != tt.wantUp {
+				t.Errorf("up = %q, want %q", up, tt.wantUp)
+			}
+			if down != tt.wantDown {
+				t.Errorf("down = %q, want %q", down, tt.wantDown)
+			}
+		})
+	}
+}
+
+func TestMaxAppliedVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied map[string]bool
+		want    string
+	}{
+		{name: "empty", applied: map[string]bool{}, want: ""},
+		{
+			name:    "picks lexicographically greatest",
+			applied: map[string]bool{"0001_a.sql": true, "0003_c.sql": true, "0002_b.sql": true},
+			want:    "0003_c.sql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maxAppliedVersion(tt.applied); got != tt.want {
+				t.Errorf("maxAppliedVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
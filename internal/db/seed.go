@@ -0,0 +1,120 @@
+// internal/db/seed.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+//go:embed seeds/*.sql
+var seedsFS embed.FS
+
+// Seed runs idempotent fixture data after Migrate has brought the schema up
+// to date, recorded in schema_seeds so each seed only ever runs once per
+// environment. Seeds are tagged by env in their filename, e.g.
+// "001_dev_sample_users.sql" only runs for env == "dev". Seeding is skipped
+// in "prod" by default, since fixtures have no business running there.
+func Seed(ctx context.Context, db *sql.DB, env string) error {
+	if env == "prod" {
+		slog.Info("skipping seeds in prod")
+		return nil
+	}
+	dialect := DialectForDriver(db)
+	SetDialect(dialect)
+	if err := createSeedsTable(ctx, db, dialect); err != nil {
+		return err
+	}
+	applied, err := getAppliedSeeds(ctx, db)
+	if err != nil {
+		return err
+	}
+	pending, err := getPendingSeeds(env, applied)
+	if err != nil {
+		return err
+	}
+	for _, name := range pending {
+		if err := runSeed(ctx, db, dialect, name); err != nil {
+			return fmt.Errorf("seed %s: %w", name, err)
+		}
+		slog.Info("applied seed", "name", name)
+	}
+	return nil
+}
+
+func createSeedsTable(ctx context.Context, db *sql.DB, dialect Dialect) error {
+	_, err := db.ExecContext(ctx, dialect.CreateSeedsTableSQL())
+	return err
+}
+
+func getAppliedSeeds(ctx context.Context, db *sql.DB) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM schema_seeds")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		applied[name] = true
+	}
+	return applied, rows.Err()
+}
+
+// seedEnv extracts the env tag from a seed filename, e.g. "dev" from
+// "001_dev_sample_users.sql". Filenames without a recognizable tag never
+// match any env and are simply never selected.
+func seedEnv(name string) string {
+	parts := strings.SplitN(strings.TrimSuffix(name, ".sql"), "_", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+func getPendingSeeds(env string, applied map[string]bool) ([]string, error) {
+	entries, err := seedsFS.ReadDir("seeds")
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		if seedEnv(e.Name()) != env || applied[e.Name()] {
+			continue
+		}
+		pending = append(pending, e.Name())
+	}
+	sort.Strings(pending)
+	return pending, nil
+}
+
+func runSeed(ctx context.Context, db *sql.DB, dialect Dialect, name string) error {
+	content, err := seedsFS.ReadFile("seeds/" + name)
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, string(content)); err != nil {
+		return err
+	}
+	insert := fmt.Sprintf("INSERT INTO schema_seeds (name, env) VALUES (%s, %s)",
+		dialect.Placeholder(1), dialect.Placeholder(2))
+	if _, err := tx.ExecContext(ctx, insert, name, seedEnv(name)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
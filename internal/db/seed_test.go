@@ -0,0 +1,23 @@
+// internal/db/seed_test.go
+package db
+
+import "testing"
+
+func TestSeedEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "001_dev_sample_users.sql", want: "dev"},
+		{name: "010_prod_admin_account.sql", want: "prod"},
+		{name: "malformed.sql", want: ""},
+		{name: "001_dev.sql", want: "dev"},
+		{name: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := seedEnv(tt.name); got != tt.want {
+			t.Errorf("seedEnv(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// internal/db/time.go
+package db
+
+import (
+	"database/sql/driver"
+	"sync"
+	"time"
+)
+
+// activeDialect governs how Time scans and stores values, since
+// database/sql's Scanner/Valuer interfaces give Time no way to receive a
+// dialect on a per-call basis. It defaults to sqlite, and Migrate/Migrator,
+// Rollback, Status, and Seed all update it to match the dialect detected for
+// the db they're given. Guarded by a mutex since several of those can run
+// concurrently in the same process.
+var (
+	activeDialectMu sync.RWMutex
+	activeDialect   Dialect = sqliteDialect{}
+)
+
+// SetDialect changes the dialect Time uses to scan and store values. Migrate
+// calls this automatically; call it yourself only if you use Time without
+// going through Migrate first.
+func SetDialect(d Dialect) {
+	activeDialectMu.Lock()
+	defer activeDialectMu.Unlock()
+	activeDialect = d
+}
+
+func currentDialect() Dialect {
+	activeDialectMu.RLock()
+	defer activeDialectMu.RUnlock()
+	return activeDialect
+}
+
+// Time wraps time.Time so its wire format can vary by Dialect (SQLite has no
+// real time type and stores RFC3339 text; Postgres and MySQL store it natively).
+type Time struct {
+	time.Time
+}
+
+func (t *Time) Scan(value interface{}) error {
+	parsed, err := currentDialect().TimeScan(value)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+func (t Time) Value() (driver.Value, error) {
+	return currentDialect().TimeValue(t.Time)
+}
+
+func Now() Time {
+	return Time{time.Now().UTC()}
+}